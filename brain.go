@@ -6,30 +6,61 @@ import (
 	"strings"
 )
 
-// Node represents a neuron in a Brain
+// Node represents a neuron in a Brain. It holds only topology (which kind of
+// node it is and which activation function it uses); the mutable per-call
+// accumulator/output live in nodeState instead, so a Node is safe to share
+// read-only across concurrent ReasonAbout calls.
 type Node struct {
-	kind                NodeKind
+	kind       NodeKind
+	activation ActivationKind
+}
+
+// nodeState holds the per-call scratch accumulator/output for one node
+// during a single ReasonAbout pass. Keeping this separate from Node means
+// concurrent ReasonAbout calls against copies of the same Brain never race
+// on shared mutable state.
+type nodeState struct {
 	accumulator, output float64
 }
 
-func (n *Node) clear() {
-	n.accumulator = 0
+func (s *nodeState) clear() {
+	s.accumulator = 0
 }
 
 func nonLinear(x float64) float64 {
 	return 1 / (1 + math.Exp(-5*x))
 }
 
-func (n *Node) activate() {
-	switch {
-	case n.kind == sensorNode:
+// activationStepEpsilon is the per-node output delta below which ReasonAbout
+// considers a recurrent network to have stabilized and stops iterating early.
+const activationStepEpsilon = 1e-6
+
+func (n Node) activate(s *nodeState) {
+	if n.kind == sensorNode {
 		return
+	}
+	switch n.activation {
+	case sigmoidActivation:
+		s.output = nonLinear(s.accumulator)
+	case tanhActivation:
+		s.output = math.Tanh(s.accumulator)
+	case reluActivation:
+		s.output = math.Max(0, s.accumulator)
+	case gaussianActivation:
+		s.output = math.Exp(-s.accumulator * s.accumulator)
+	case sineActivation:
+		s.output = math.Sin(s.accumulator)
+	case linearActivation:
+		s.output = s.accumulator
 	default:
-		n.output = nonLinear(n.accumulator)
+		s.output = nonLinear(s.accumulator)
 	}
 }
 
-// Connection represents a synapse between nodes in a Brain
+// Connection represents a synapse between nodes in a Brain. Unlike
+// connectionGene.from/to, these are positions into Brain.nodes: BuildBrain
+// resolves the gene's stable node mutationIDs down to array positions once,
+// so ReasonAbout can index nodeState slices directly.
 type Connection struct {
 	from, to int
 	weight   float64
@@ -46,12 +77,21 @@ type Brain struct {
 
 // BuildBrain builds a neural network as described by a Genome
 func BuildBrain(genes Genome) Brain {
-	nodes, connections := []Node{}, []Connection{}
+	nodes := []Node{}
+	position := map[int]int{}
 	var inputs, outputs int
+
+	type pendingConnection struct {
+		from, to int // node mutationIDs, translated to positions below
+		weight   float64
+	}
+	pendingConnections := []pendingConnection{}
+
 	for _, g := range genes {
 		if strings.HasPrefix(g.payload, "n") {
 			nodeGene := decodeNodeGene(g.payload)
-			node := Node{nodeGene.kind, 0, 0}
+			position[nodeGene.mutationID] = len(nodes)
+			node := Node{nodeGene.kind, nodeGene.activation}
 			nodes = append(nodes, node)
 			if node.kind == sensorNode {
 				inputs++
@@ -61,45 +101,111 @@ func BuildBrain(genes Genome) Brain {
 		} else if strings.HasPrefix(g.payload, "c") {
 			connGene := decodeConnectionGene(g.payload)
 			if connGene.enabled {
-				conn := Connection{connGene.from, connGene.to, connGene.weight}
-				connections = append(connections, conn)
+				pendingConnections = append(pendingConnections, pendingConnection{connGene.from, connGene.to, connGene.weight})
 			}
+		} else if strings.HasPrefix(g.payload, "m") {
+			// Module genes are control/metadata genes describing a reusable
+			// subgraph; the nodes and connections that make up the module's
+			// body are already present as their own "n" and "c" genes, so
+			// there is nothing extra to build here.
 		} else {
 			log.Fatalf("Unknown gene signature: %s", g.payload)
 		}
 	}
 
+	// Crossover can produce a child that inherits a connection gene without
+	// one (or both) of the node genes it references, since disjoint/excess
+	// node and connection genes are included independently under tied
+	// fitness. Resolve from/to through the positions actually built above,
+	// and drop any connection whose endpoint didn't survive rather than
+	// building a dangling Connection that would index out of range.
+	connections := make([]Connection, 0, len(pendingConnections))
+	for _, c := range pendingConnections {
+		from, okFrom := position[c.from]
+		to, okTo := position[c.to]
+		if !okFrom || !okTo {
+			continue
+		}
+		connections = append(connections, Connection{from, to, c.weight})
+	}
+
 	return Brain{nodes, connections, genes.encode(), inputs, outputs, 0}
 }
 
-// ReasonAbout loads an input vector, runs it through the Brain, and returns the output
-func (b Brain) ReasonAbout(inputs []float64) ([]float64, Brain) {
-	// Clear nodes
-	for i := range b.nodes {
-		b.nodes[i].clear()
-	}
+// EvaluationContext holds the per-node outputs produced by one ReasonAbout
+// pass. It is allocated fresh for each call rather than stored on the Brain,
+// so concurrent or repeated evaluations of copies of the same Brain never
+// share mutable state.
+type EvaluationContext struct {
+	Outputs []float64
+}
+
+// ReasonAbout loads an input vector and relaxes the Brain over up to steps
+// activation passes, returning the output once it stabilizes (or steps is
+// exhausted). Every connection reads its source node's output from the
+// previous pass, so a signal advances one connection per step: a path through
+// hidden nodes needs as many steps as it has connections to reach the
+// outputs, and a steps value greater than 1 is what turns a cyclic topology
+// into an actual feedback loop rather than a dangling edge.
+func (b Brain) ReasonAbout(inputs []float64, steps int) ([]float64, EvaluationContext) {
+	states := make([]nodeState, len(b.nodes))
 
 	// Load inputs
 	for i := 0; i < b.Inputs; i++ {
-		b.nodes[i].output = inputs[i]
+		states[i].output = inputs[i]
 	}
 
-	// Apply connections
-	for _, c := range b.connections {
-		x := b.nodes[c.from].output
-		b.nodes[c.to].accumulator += x * c.weight
-	}
+	prevOutputs := make([]float64, len(b.nodes))
+	for step := 0; step < steps; step++ {
+		for i := range states {
+			prevOutputs[i] = states[i].output
+			states[i].clear()
+		}
+
+		// Apply connections using the previous pass's outputs
+		for _, c := range b.connections {
+			states[c.to].accumulator += prevOutputs[c.from] * c.weight
+		}
 
-	// Activate nodes
-	for i := range b.nodes {
-		b.nodes[i].activate()
+		// Activate nodes
+		for i := range states {
+			b.nodes[i].activate(&states[i])
+		}
+
+		var maxDelta float64
+		for i := b.Inputs; i < len(states); i++ {
+			if delta := math.Abs(states[i].output - prevOutputs[i]); delta > maxDelta {
+				maxDelta = delta
+			}
+		}
+		if maxDelta < activationStepEpsilon {
+			break
+		}
 	}
 
 	// Read outputs
 	output := make([]float64, b.Outputs)
 	for o := 0; o < b.Outputs; o++ {
-		output[o] = b.nodes[o+b.Inputs].output
+		output[o] = states[o+b.Inputs].output
+	}
+
+	ctx := EvaluationContext{Outputs: make([]float64, len(states))}
+	for i := range states {
+		ctx.Outputs[i] = states[i].output
 	}
 
-	return output, b
+	return output, ctx
+}
+
+// clone returns a Brain with its own copies of the node/connection slices, so
+// a FitnessEval running in a worker goroutine can mutate or evaluate it
+// without racing other workers evaluating copies of the same genome.
+func (b Brain) clone() Brain {
+	nodes := make([]Node, len(b.nodes))
+	copy(nodes, b.nodes)
+	connections := make([]Connection, len(b.connections))
+	copy(connections, b.connections)
+	b.nodes = nodes
+	b.connections = connections
+	return b
 }
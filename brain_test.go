@@ -0,0 +1,34 @@
+package neat
+
+import "testing"
+
+// TestReasonAboutRecurrentTopologyNeedsMultipleSteps builds a brain with a
+// self-recurrent hidden node by hand (a sensor feeding a hidden node that
+// feeds back into itself before driving the output) and checks that a single
+// activation pass gives a different answer than letting ReasonAbout relax
+// the network over enough steps to settle near its fixed point, and that
+// once settled, further steps don't change the answer.
+func TestReasonAboutRecurrentTopologyNeedsMultipleSteps(t *testing.T) {
+	nodes := []nodeGene{
+		{0, sensorNode, linearActivation, 0},
+		{1, outputNode, linearActivation, 0},
+		{2, hiddenNode, linearActivation, 0},
+	}
+	conns := []connectionGene{
+		{3, 0, 2, 1, true},   // sensor -> hidden
+		{4, 2, 1, 1, true},   // hidden -> output
+		{5, 2, 2, 0.5, true}, // hidden -> itself
+	}
+	brain := BuildBrain(encodeGenes(nodes, conns, nil))
+
+	oneStep, _ := brain.ReasonAbout([]float64{1}, 1)
+	settled, _ := brain.ReasonAbout([]float64{1}, 50)
+	stillSettled, _ := brain.ReasonAbout([]float64{1}, 100)
+
+	if oneStep[0] == settled[0] {
+		t.Fatalf("expected a single activation pass to differ from a relaxed one on a recurrent topology, got %v for both", oneStep[0])
+	}
+	if settled[0] != stillSettled[0] {
+		t.Fatalf("expected the network to have stabilized by 50 steps: got %v at 50 steps, %v at 100", settled[0], stillSettled[0])
+	}
+}
@@ -33,27 +33,51 @@ const (
 	hiddenNode
 )
 
+// ActivationKind is an enum of the possible per-node activation functions
+type ActivationKind int
+
+const (
+	sigmoidActivation ActivationKind = iota
+	tanhActivation
+	reluActivation
+	gaussianActivation
+	sineActivation
+	linearActivation
+	numActivationKinds
+)
+
 type nodeGene struct {
 	mutationID int
 	kind       NodeKind
+	activation ActivationKind
+	// moduleID is the mutationID of the moduleGene this node belongs to, or
+	// 0 if the node is not part of a module.
+	moduleID int
 }
 
 func (g nodeGene) encode() string {
-	return fmt.Sprintf("n,%d,%d;", g.mutationID, g.kind)
+	return fmt.Sprintf("n,%d,%d,%d,%d;", g.mutationID, g.kind, g.activation, g.moduleID)
 }
 
 func decodeNodeGene(s string) nodeGene {
 	components := strings.Split(strings.Split(s, ";")[0], ",")
 	id, _ := strconv.Atoi(components[1])
 	kind, _ := strconv.Atoi(components[2])
-	return nodeGene{id, NodeKind(kind)}
+	activation, _ := strconv.Atoi(components[3])
+	moduleID, _ := strconv.Atoi(components[4])
+	return nodeGene{id, NodeKind(kind), ActivationKind(activation), moduleID}
 }
 
 type connectionGene struct {
 	mutationID int
-	from, to   int
-	weight     float64
-	enabled    bool
+	// from and to are the mutationIDs of the node genes this connection
+	// joins, not array positions: a child genome's node genes can end up in
+	// a different order (or drop genes entirely) than either parent's, so a
+	// position would desync the moment a Crossover child's node set diverges
+	// from the genome this connection was created against.
+	from, to int
+	weight   float64
+	enabled  bool
 }
 
 func (g connectionGene) encode() string {
@@ -61,7 +85,7 @@ func (g connectionGene) encode() string {
 	if g.enabled {
 		enabledBit = 1
 	}
-	return fmt.Sprintf("c,%d,%d,%d,%x,%d;", g.mutationID, g.from, g.to, math.Float64bits(g.weight), enabledBit)
+	return fmt.Sprintf("c,%d,%d,%d,%016x,%d;", g.mutationID, g.from, g.to, math.Float64bits(g.weight), enabledBit)
 }
 
 func decodeConnectionGene(s string) connectionGene {
@@ -83,6 +107,47 @@ func decodeConnectionGene(s string) connectionGene {
 	return connectionGene{id, from, to, w, enabled}
 }
 
+// moduleGene is a control gene describing a reusable subgraph: a module's
+// body is just the regular node/connection genes whose moduleID matches this
+// gene's mutationID, and inputs/outputs record the IDs of the external nodes
+// that feed into, and are fed by, that body.
+type moduleGene struct {
+	mutationID      int
+	inputs, outputs []int
+}
+
+func (g moduleGene) encode() string {
+	return fmt.Sprintf("m,%d,%s,%s;", g.mutationID, joinInts(g.inputs), joinInts(g.outputs))
+}
+
+func decodeModuleGene(s string) moduleGene {
+	components := strings.Split(strings.Split(s, ";")[0], ",")
+	id, _ := strconv.Atoi(components[1])
+	inputs := splitInts(components[2])
+	outputs := splitInts(components[3])
+	return moduleGene{id, inputs, outputs}
+}
+
+func joinInts(xs []int) string {
+	strs := make([]string, len(xs))
+	for i, x := range xs {
+		strs[i] = strconv.Itoa(x)
+	}
+	return strings.Join(strs, "-")
+}
+
+func splitInts(s string) []int {
+	if s == "" {
+		return []int{}
+	}
+	parts := strings.Split(s, "-")
+	xs := make([]int, len(parts))
+	for i, p := range parts {
+		xs[i], _ = strconv.Atoi(p)
+	}
+	return xs
+}
+
 // Genome represents the genes for a neural net
 type Genome []Gene
 
@@ -115,7 +180,7 @@ func DecodeGenome(s string) Genome {
 	return Genome(G)
 }
 
-func encodeGenes(nodes []nodeGene, conns []connectionGene) Genome {
+func encodeGenes(nodes []nodeGene, conns []connectionGene, modules []moduleGene) Genome {
 	G := []Gene{}
 	for _, node := range nodes {
 		g := Gene{node.mutationID, node.encode()}
@@ -125,12 +190,16 @@ func encodeGenes(nodes []nodeGene, conns []connectionGene) Genome {
 		g := Gene{conn.mutationID, conn.encode()}
 		G = append(G, g)
 	}
+	for _, m := range modules {
+		g := Gene{m.mutationID, m.encode()}
+		G = append(G, g)
+	}
 	sort.Sort(byMutationID(G))
 	return Genome(G)
 }
 
-func decodeGenes(G Genome) ([]nodeGene, []connectionGene) {
-	nodes, conns := []nodeGene{}, []connectionGene{}
+func decodeGenes(G Genome) ([]nodeGene, []connectionGene, []moduleGene) {
+	nodes, conns, modules := []nodeGene{}, []connectionGene{}, []moduleGene{}
 	for _, g := range G {
 		if strings.HasPrefix(g.payload, "n") {
 			node := decodeNodeGene(g.payload)
@@ -138,9 +207,11 @@ func decodeGenes(G Genome) ([]nodeGene, []connectionGene) {
 		} else if strings.HasPrefix(g.payload, "c") {
 			conn := decodeConnectionGene(g.payload)
 			conns = append(conns, conn)
+		} else if strings.HasPrefix(g.payload, "m") {
+			modules = append(modules, decodeModuleGene(g.payload))
 		}
 	}
-	return nodes, conns
+	return nodes, conns, modules
 }
 
 // StartingGenome produces a Genome with the minimum nodes and connections for a set of inputs and outputs
@@ -148,13 +219,13 @@ func StartingGenome(inputs, outputs int) (Genome, int) {
 	G := []Gene{}
 	var mutID int
 	for i := 0; i < inputs; i++ {
-		node := nodeGene{mutID, sensorNode}
+		node := nodeGene{mutID, sensorNode, sigmoidActivation, 0}
 		gene := Gene{mutID, node.encode()}
 		G = append(G, gene)
 		mutID++
 	}
 	for i := 0; i < outputs; i++ {
-		node := nodeGene{mutID, outputNode}
+		node := nodeGene{mutID, outputNode, sigmoidActivation, 0}
 		gene := Gene{mutID, node.encode()}
 		G = append(G, gene)
 		mutID++
@@ -179,6 +250,9 @@ type MutationKind int
 const (
 	addNodeMutation MutationKind = iota
 	addConnectionMutation
+	flipActivationMutation
+	addModuleMutation
+	duplicateModuleMutation
 )
 
 // Mutation holds information about a mutation that occurred this generation
@@ -190,7 +264,7 @@ type Mutation struct {
 
 // Mutate alters an existing Genome to simulation random mutations
 func (G Genome) Mutate(nextID int, mtg []Mutation) (Genome, int, []Mutation) {
-	nodes, conns := decodeGenes(G)
+	nodes, conns, modules := decodeGenes(G)
 
 	if rand.Float64() < 0.8 {
 		conns = mutateConnectionWeights(conns)
@@ -242,10 +316,83 @@ func (G Genome) Mutate(nextID int, mtg []Mutation) (Genome, int, []Mutation) {
 		}
 	}
 
-	G = encodeGenes(nodes, conns)
+	if rand.Float64() < 0.02 {
+		idx, newActivation, ok := mutateFlipActivation(nodes)
+		if ok {
+			nodes[idx].activation = newActivation
+		}
+	}
+
+	if rand.Float64() < 0.01 {
+		mg, seed, ok := mutateAddModule(nextID, nodes, conns)
+		if ok {
+			found := false
+			for _, m := range mtg {
+				if m.kind == addModuleMutation && m.from == seed {
+					for i := range nodes {
+						if nodes[i].moduleID == mg.mutationID {
+							nodes[i].moduleID = m.mutationID
+						}
+					}
+					mg.mutationID = m.mutationID
+					found = true
+					break
+				}
+			}
+			if !found {
+				mtg = append(mtg, Mutation{mg.mutationID, addModuleMutation, seed, 0})
+				nextID++
+			}
+			modules = append(modules, mg)
+		}
+	}
+
+	if rand.Float64() < 0.01 {
+		plan, ok := planDuplicateModule(nodes, conns, modules)
+		if ok {
+			base := nextID
+			found := false
+			for _, m := range mtg {
+				if m.kind == duplicateModuleMutation && m.from == plan.srcID {
+					base = m.mutationID
+					found = true
+					break
+				}
+			}
+			mg, dupNodes, dupConns, newNextID := realizeDuplicateModule(base, plan)
+			if !found {
+				mtg = append(mtg, Mutation{base, duplicateModuleMutation, plan.srcID, 0})
+				nextID = newNextID
+			}
+			nodes = append(nodes, dupNodes...)
+			conns = append(conns, dupConns...)
+			modules = append(modules, mg)
+		}
+	}
+
+	G = encodeGenes(nodes, conns, modules)
 	return G, nextID, mtg
 }
 
+// mutateFlipActivation picks a random non-sensor node and reassigns it to a
+// different activation function, giving recurrent networks a way to evolve
+// the nonlinearity each node computes rather than just its wiring.
+func mutateFlipActivation(nodes []nodeGene) (int, ActivationKind, bool) {
+	candidates := []int{}
+	for i, n := range nodes {
+		if n.kind != sensorNode {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, 0, false
+	}
+	idx := candidates[rand.Intn(len(candidates))]
+	current := nodes[idx].activation
+	next := ActivationKind((int(current) + 1 + rand.Intn(int(numActivationKinds)-1)) % int(numActivationKinds))
+	return idx, next, true
+}
+
 func mutateConnectionWeights(conns []connectionGene) []connectionGene {
 	for i, c := range conns {
 		if rand.Float64() < 0.9 {
@@ -262,17 +409,21 @@ func mutateAddConnection(nextID int, nodes []nodeGene, conns []connectionGene) (
 			inputs++
 		}
 	}
+// Node order no longer implies evaluation order now that ReasonAbout relaxes
+// the network over multiple steps, so i is free to land on or after j and
+// form a recurrent/backward edge.
 search:
 	for try := 0; try < 10; try++ {
 		i := rand.Intn(len(nodes))
 		j := rand.Intn(len(nodes)-inputs) + inputs
+		from, to := nodes[i].mutationID, nodes[j].mutationID
 		for _, c := range conns {
-			if c.from == i && c.to == j {
+			if c.from == from && c.to == to {
 				continue search
 			}
-			w := uniform(-2, 2)
-			return connectionGene{nextID, i, j, w, true}, true
 		}
+		w := uniform(-2, 2)
+		return connectionGene{nextID, from, to, w, true}, true
 	}
 	return connectionGene{}, false
 }
@@ -288,11 +439,11 @@ search:
 		}
 
 		i := conn.from
-		j := len(nodes)
 		k := conn.to
 
 		conn.enabled = false
-		n := nodeGene{nextID, hiddenNode}
+		n := nodeGene{nextID, hiddenNode, ActivationKind(rand.Intn(int(numActivationKinds))), 0}
+		j := n.mutationID
 		a := connectionGene{nextID + 1, i, j, 1, true}
 		b := connectionGene{nextID + 2, j, k, conn.weight, true}
 
@@ -301,6 +452,176 @@ search:
 	return connectionGene{}, connectionGene{}, nodeGene{}, connectionGene{}, false
 }
 
+// mutateAddModule groups an unclaimed hidden node together with its directly
+// connected hidden neighbors into a new module, tagging their moduleID so the
+// group can later be duplicated as a unit by planDuplicateModule and
+// realizeDuplicateModule. The
+// module's inputs/outputs record the external nodes that cross its boundary.
+// It also returns the seed node's mutationID, which Mutate uses to recognize
+// when two siblings grouped the same module in one generation so they can
+// share a moduleID instead of fragmenting into incompatible species.
+func mutateAddModule(nextID int, nodes []nodeGene, conns []connectionGene) (moduleGene, int, bool) {
+	byID := nodeIndexByID(nodes)
+
+	candidates := []int{}
+	for _, n := range nodes {
+		if n.kind == hiddenNode && n.moduleID == 0 {
+			candidates = append(candidates, n.mutationID)
+		}
+	}
+	if len(candidates) == 0 {
+		return moduleGene{}, 0, false
+	}
+	seed := candidates[rand.Intn(len(candidates))]
+
+	members := map[int]bool{seed: true}
+	for _, c := range conns {
+		if c.from == seed {
+			if idx, ok := byID[c.to]; ok && nodes[idx].kind == hiddenNode && nodes[idx].moduleID == 0 {
+				members[c.to] = true
+			}
+		}
+		if c.to == seed {
+			if idx, ok := byID[c.from]; ok && nodes[idx].kind == hiddenNode && nodes[idx].moduleID == 0 {
+				members[c.from] = true
+			}
+		}
+	}
+
+	ins, outs := map[int]bool{}, map[int]bool{}
+	for _, c := range conns {
+		if members[c.to] && !members[c.from] {
+			ins[c.from] = true
+		}
+		if members[c.from] && !members[c.to] {
+			outs[c.to] = true
+		}
+	}
+
+	moduleID := nextID
+	for id := range members {
+		nodes[byID[id]].moduleID = moduleID
+	}
+
+	return moduleGene{moduleID, intSetToSlice(ins), intSetToSlice(outs)}, seed, true
+}
+
+// modulePlan describes which existing node and connection genes
+// planDuplicateModule would clone, decoupled from the mutationIDs that get
+// assigned to the clones. Keeping the random pick of which module to
+// duplicate (plan) separate from ID assignment (realizeDuplicateModule) lets
+// Mutate redo the assignment against a previously recorded base when two
+// siblings duplicate the same module in one generation.
+type modulePlan struct {
+	srcID           int
+	members         []nodeGene
+	connSpecs       []dupConnSpec
+	inputs, outputs []int
+}
+
+// dupConnSpec describes a connection gene that planDuplicateModule decided to
+// clone, in terms of its original endpoints: fromDup/toDup mark which
+// endpoints land on a duplicated node (and so must be remapped to the
+// duplicate's new ID) versus an external boundary node (kept as-is).
+type dupConnSpec struct {
+	from, to       int
+	fromDup, toDup bool
+	weight         float64
+	enabled        bool
+}
+
+// planDuplicateModule picks a random existing module and records which node
+// and connection genes belong to it, without assigning any new mutationIDs.
+func planDuplicateModule(nodes []nodeGene, conns []connectionGene, modules []moduleGene) (modulePlan, bool) {
+	if len(modules) == 0 {
+		return modulePlan{}, false
+	}
+	src := modules[rand.Intn(len(modules))]
+
+	members := map[int]bool{}
+	memberNodes := []nodeGene{}
+	for _, n := range nodes {
+		if n.moduleID == src.mutationID {
+			members[n.mutationID] = true
+			memberNodes = append(memberNodes, n)
+		}
+	}
+	if len(memberNodes) == 0 {
+		return modulePlan{}, false
+	}
+
+	connSpecs := []dupConnSpec{}
+	for _, c := range conns {
+		fromDup, toDup := members[c.from], members[c.to]
+		if !fromDup && !toDup {
+			continue
+		}
+		connSpecs = append(connSpecs, dupConnSpec{c.from, c.to, fromDup, toDup, c.weight, c.enabled})
+	}
+
+	return modulePlan{src.mutationID, memberNodes, connSpecs, append([]int{}, src.inputs...), append([]int{}, src.outputs...)}, true
+}
+
+// realizeDuplicateModule assigns mutationIDs, starting at base, to the node
+// and connection genes a modulePlan describes, and returns the moduleGene for
+// the resulting copy along with the next unused mutationID. Calling this
+// twice with the same base and plan always produces identical genes, which
+// is what lets Mutate reuse a previously recorded base for a repeated
+// duplicate-module mutation instead of minting new IDs for it.
+func realizeDuplicateModule(base int, plan modulePlan) (moduleGene, []nodeGene, []connectionGene, int) {
+	nextID := base
+
+	remap := map[int]int{} // old node mutationID -> duplicate node mutationID
+	dupNodes := make([]nodeGene, 0, len(plan.members))
+	for _, n := range plan.members {
+		dup := n
+		dup.mutationID = nextID
+		remap[n.mutationID] = nextID
+		nextID++
+		dupNodes = append(dupNodes, dup)
+	}
+
+	dupConns := make([]connectionGene, 0, len(plan.connSpecs))
+	for _, spec := range plan.connSpecs {
+		dup := connectionGene{nextID, spec.from, spec.to, spec.weight, spec.enabled}
+		nextID++
+		if spec.fromDup {
+			dup.from = remap[spec.from]
+		}
+		if spec.toDup {
+			dup.to = remap[spec.to]
+		}
+		dupConns = append(dupConns, dup)
+	}
+
+	moduleID := nextID
+	nextID++
+	for i := range dupNodes {
+		dupNodes[i].moduleID = moduleID
+	}
+
+	return moduleGene{moduleID, append([]int{}, plan.inputs...), append([]int{}, plan.outputs...)}, dupNodes, dupConns, nextID
+}
+
+// nodeIndexByID maps each node gene's mutationID to its position in nodes,
+// for call sites that need to look a node up by ID rather than scan for it.
+func nodeIndexByID(nodes []nodeGene) map[int]int {
+	byID := make(map[int]int, len(nodes))
+	for i, n := range nodes {
+		byID[n.mutationID] = i
+	}
+	return byID
+}
+
+func intSetToSlice(set map[int]bool) []int {
+	xs := make([]int, 0, len(set))
+	for x := range set {
+		xs = append(xs, x)
+	}
+	sort.Ints(xs)
+	return xs
+}
+
 func genomeMismatch(a, b Genome) (float64, float64, float64, float64, float64) {
 	A, B := float64(len(a)), float64(len(b))
 	N := math.Max(A, B)
@@ -350,3 +671,85 @@ func Sharing(a, b Genome) float64 {
 	}
 	return 0
 }
+
+// Crossover implements NEAT's innovation-preserving recombination (Stanley &
+// Miikkulainen, 2002). Genes are walked in mutationID order: matching genes
+// (present in both parents) are inherited from a randomly chosen parent,
+// re-enabling a disabled connection gene with ~25% probability if either
+// parent's copy of it is disabled. Disjoint and excess genes (present in
+// only one parent) are inherited only from the more fit parent, or randomly
+// from either parent if aFit and bFit are equal.
+func Crossover(a, b Genome, aFit, bFit float64) Genome {
+	as := append(Genome{}, a...)
+	bs := append(Genome{}, b...)
+	sort.Sort(byMutationID(as))
+	sort.Sort(byMutationID(bs))
+
+	child := []Gene{}
+	i, j := 0, 0
+	for i < len(as) && j < len(bs) {
+		switch {
+		case as[i].mutationID == bs[j].mutationID:
+			var selected, other Gene
+			if rand.Float64() < 0.5 {
+				selected, other = as[i], bs[j]
+			} else {
+				selected, other = bs[j], as[i]
+			}
+			child = append(child, inheritMatchingGene(selected, other))
+			i++
+			j++
+		case as[i].mutationID < bs[j].mutationID:
+			if includeDisjointOrExcess(aFit, bFit) {
+				child = append(child, as[i])
+			}
+			i++
+		default:
+			if includeDisjointOrExcess(bFit, aFit) {
+				child = append(child, bs[j])
+			}
+			j++
+		}
+	}
+	for ; i < len(as); i++ {
+		if includeDisjointOrExcess(aFit, bFit) {
+			child = append(child, as[i])
+		}
+	}
+	for ; j < len(bs); j++ {
+		if includeDisjointOrExcess(bFit, aFit) {
+			child = append(child, bs[j])
+		}
+	}
+
+	sort.Sort(byMutationID(child))
+	return Genome(child)
+}
+
+// includeDisjointOrExcess decides whether a disjoint/excess gene belonging to
+// the parent with fitness fit should be inherited, given the other parent's
+// fitness otherFit.
+func includeDisjointOrExcess(fit, otherFit float64) bool {
+	if fit > otherFit {
+		return true
+	}
+	if fit < otherFit {
+		return false
+	}
+	return rand.Float64() < 0.5
+}
+
+// inheritMatchingGene returns the selected copy of a matching gene, unless it
+// is a connection gene and either parent's copy is disabled, in which case
+// the inherited copy is re-enabled with ~25% probability.
+func inheritMatchingGene(selected, other Gene) Gene {
+	if strings.HasPrefix(selected.payload, "c") && strings.HasPrefix(other.payload, "c") {
+		mine := decodeConnectionGene(selected.payload)
+		theirs := decodeConnectionGene(other.payload)
+		if !mine.enabled || !theirs.enabled {
+			mine.enabled = rand.Float64() < 0.25
+			selected.payload = mine.encode()
+		}
+	}
+	return selected
+}
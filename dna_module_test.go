@@ -0,0 +1,55 @@
+package neat
+
+import "testing"
+
+// TestModuleAddDuplicateCrossoverRoundTrip exercises module genes end to end:
+// group two hidden nodes into a module, duplicate it, then recombine the
+// resulting genome with itself under tied fitness (the Crossover scenario
+// chunk0-5 fixed) and confirm BuildBrain/ReasonAbout can still build and run
+// it without a dangling connection reference.
+func TestModuleAddDuplicateCrossoverRoundTrip(t *testing.T) {
+	nodes := []nodeGene{
+		{0, sensorNode, linearActivation, 0},
+		{1, outputNode, linearActivation, 0},
+		{2, hiddenNode, linearActivation, 0},
+		{3, hiddenNode, linearActivation, 0},
+	}
+	conns := []connectionGene{
+		{4, 0, 2, 1, true},
+		{5, 2, 3, 1, true},
+		{6, 3, 1, 1, true},
+	}
+	nextID := 7
+
+	mg, _, ok := mutateAddModule(nextID, nodes, conns)
+	if !ok {
+		t.Fatal("mutateAddModule: expected a candidate hidden node to group into a module")
+	}
+	nextID++
+	if nodes[2].moduleID != mg.mutationID || nodes[3].moduleID != mg.mutationID {
+		t.Fatalf("expected both hidden nodes tagged with the new module's ID %d, got %d and %d", mg.mutationID, nodes[2].moduleID, nodes[3].moduleID)
+	}
+
+	plan, ok := planDuplicateModule(nodes, conns, []moduleGene{mg})
+	if !ok {
+		t.Fatal("planDuplicateModule: expected the just-created module to be a valid duplication source")
+	}
+	dupModule, dupNodes, dupConns, _ := realizeDuplicateModule(nextID, plan)
+	if len(dupNodes) != 2 || len(dupConns) != 3 {
+		t.Fatalf("expected the duplicate to carry 2 nodes and 3 connections, got %d nodes and %d connections", len(dupNodes), len(dupConns))
+	}
+
+	allNodes := append(append([]nodeGene{}, nodes...), dupNodes...)
+	allConns := append(append([]connectionGene{}, conns...), dupConns...)
+	genome := encodeGenes(allNodes, allConns, []moduleGene{mg, dupModule})
+
+	child := Crossover(genome, genome, 1.0, 1.0)
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("BuildBrain/ReasonAbout panicked on a module add+duplicate+crossover round trip: %v", r)
+		}
+	}()
+	brain := BuildBrain(child)
+	brain.ReasonAbout([]float64{1}, 5)
+}
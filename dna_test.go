@@ -0,0 +1,42 @@
+package neat
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestCrossoverTiedFitnessDoesNotCorruptIndices reproduces the scenario the
+// crossover bugfix addressed: two diverged lineages recombined under tied
+// fitness, which used to let a disjoint connection gene survive without the
+// node gene its position depended on (or vice versa), corrupting every other
+// connection's index and panicking BuildBrain/ReasonAbout. With from/to
+// addressed by stable node mutationID instead of array position, the worst
+// a missing endpoint can do is get dropped as a dangling edge.
+func TestCrossoverTiedFitnessDoesNotCorruptIndices(t *testing.T) {
+	rand.Seed(0)
+
+	const trials = 500
+	for trial := 0; trial < trials; trial++ {
+		genes, nextID := StartingGenome(3, 2)
+		a, b := genes, genes
+		mtg := []Mutation{}
+		for i := 0; i < 15; i++ {
+			a, nextID, mtg = a.Mutate(nextID, mtg)
+		}
+		for i := 0; i < 15; i++ {
+			b, nextID, mtg = b.Mutate(nextID, mtg)
+		}
+
+		child := Crossover(a, b, 1.0, 1.0)
+
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("trial %d: BuildBrain/ReasonAbout panicked on tied-fitness crossover child: %v", trial, r)
+				}
+			}()
+			brain := BuildBrain(child)
+			brain.ReasonAbout([]float64{1, 0, 1}, 5)
+		}()
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"math/rand"
 	"sort"
+	"sync"
 )
 
 // Species represents a collection of Brains that share Genome traits
@@ -49,9 +50,22 @@ func (p Population) size() int {
 
 // Optimize uses a FitnessEval to identify low-performing candidate Genomes and replace them with new ones based on well-performing candidates
 func (p *Population) Optimize(f FitnessEval, numGenerations int) {
+	p.optimize(f, numGenerations, 1)
+}
+
+// OptimizeParallel behaves like Optimize, but evaluates each generation's
+// fitnesses across a pool of workers goroutines instead of serially. Each
+// worker is handed its own deep copy of the Brain being evaluated, so f is
+// free to mutate it (e.g. to cache per-call state) without racing other
+// workers evaluating the same generation.
+func (p *Population) OptimizeParallel(f FitnessEval, numGenerations, workers int) {
+	p.optimize(f, numGenerations, workers)
+}
+
+func (p *Population) optimize(f FitnessEval, numGenerations, workers int) {
 	N := p.size()
 
-	p.updateFitnesses(f)
+	p.updateFitnesses(f, workers)
 	n, c := p.Champion.nodes, p.Champion.connections
 	fmt.Printf("Gen %d: %d specimens in %d species, %.2f best score with %d nodes %d connections\n", 0, p.size(), len(p.species), p.Champion.fitness, len(n), len(c))
 
@@ -140,46 +154,11 @@ func (p *Population) Optimize(f FitnessEval, numGenerations int) {
 					// Sexual reproduction
 					mb, fb := s.members[rand.Intn(numParents)], s.members[rand.Intn(numParents)]
 					mg, fg := DecodeGenome(mb.Genes), DecodeGenome(fb.Genes)
-					_, matchCount, _, _, _ := genomeMismatch(mg, fg)
-					mGenes, fGenes := make([]Gene, int(matchCount)), make([]Gene, int(matchCount))
-					for j := 0; j < int(matchCount); j++ {
-						// if rand.Float64() < 0.01 {
-						// 	if strings.HasPrefix(mg[j].payload, "c") {
-						// 		temp := decodeConnectionGene(mg[j].payload)
-						// 		temp.enabled = true
-						// 		mg[j].payload = temp.encode()
-						// 	}
-						// 	if strings.HasPrefix(fg[j].payload, "c") {
-						// 		temp := decodeConnectionGene(fg[j].payload)
-						// 		temp.enabled = true
-						// 		fg[j].payload = temp.encode()
-						// 	}
-						// }
-						mGenes[j], fGenes[j] = mg[j], fg[j]
-					}
-					var otherGenes []Gene
-					switch {
-					case mb.fitness > fb.fitness && len(mg) > len(fg):
-						otherGenes = mg[int(matchCount):]
-					case fb.fitness > mb.fitness && len(fg) > len(mg):
-						otherGenes = fg[int(matchCount):]
-					default:
-						otherGenes = []Gene{}
-					}
-					mGenes, fGenes = append(mGenes, otherGenes...), append(fGenes, otherGenes...)
-					aGenes, bGenes := make([]Gene, len(mGenes)), make([]Gene, len(mGenes))
-					for j := range aGenes {
-						var left, right Gene
-						if rand.Float64() < 0.5 {
-							left, right = mGenes[j], fGenes[j]
-						} else {
-							right, left = mGenes[j], fGenes[j]
-						}
-						aGenes[j], bGenes[j] = left, right
-					}
-					var aGenome, bGenome Genome
-					aGenome, p.nextID, mtg = Genome(aGenes).Mutate(p.nextID, mtg)
-					bGenome, p.nextID, mtg = Genome(bGenes).Mutate(p.nextID, mtg)
+
+					aGenome := Crossover(mg, fg, mb.fitness, fb.fitness)
+					bGenome := Crossover(fg, mg, fb.fitness, mb.fitness)
+					aGenome, p.nextID, mtg = aGenome.Mutate(p.nextID, mtg)
+					bGenome, p.nextID, mtg = bGenome.Mutate(p.nextID, mtg)
 					s.members = append(s.members, BuildBrain(aGenome), BuildBrain(bGenome))
 				}
 			}
@@ -187,7 +166,7 @@ func (p *Population) Optimize(f FitnessEval, numGenerations int) {
 		}
 
 		p.updateSpeciation()
-		p.updateFitnesses(f)
+		p.updateFitnesses(f, workers)
 		// sort.Sort(byChampFitness(p.species))
 
 		n, c := p.Champion.nodes, p.Champion.connections
@@ -210,7 +189,7 @@ func (b byFitness) Len() int           { return len(b) }
 func (b byFitness) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
 func (b byFitness) Less(i, j int) bool { return b[i].fitness > b[j].fitness }
 
-func (p *Population) updateFitnesses(f FitnessEval) {
+func (p *Population) updateFitnesses(f FitnessEval, workers int) {
 	for i, s := range p.species {
 		var prevBestFitness float64
 		if s.champion == nil {
@@ -219,9 +198,7 @@ func (p *Population) updateFitnesses(f FitnessEval) {
 			prevBestFitness = s.champion.fitness
 		}
 
-		for j, b := range s.members {
-			s.members[j].fitness = f(b)
-		}
+		evaluateMembers(s.members, f, workers)
 		sort.Sort(byFitness(s.members))
 
 		for j := range s.members {
@@ -292,3 +269,35 @@ func tanhCutoff(i, N int) float64 {
 	m := 5.0
 	return 0.5 * (1 + math.Tanh(2*m*x/n-m))
 }
+
+// evaluateMembers assigns f(b) to each member's fitness. With workers <= 1 it
+// evaluates serially in place, matching the original behavior. With workers >
+// 1 it fans the work out across that many goroutines, each pulling indices
+// off a shared jobs channel and calling f with its own deep copy of the
+// Brain so concurrent evaluations of the same generation never share state.
+func evaluateMembers(members []Brain, f FitnessEval, workers int) {
+	if workers <= 1 {
+		for j, b := range members {
+			members[j].fitness = f(b)
+		}
+		return
+	}
+
+	jobs := make(chan int, len(members))
+	for j := range members {
+		jobs <- j
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				members[j].fitness = f(members[j].clone())
+			}
+		}()
+	}
+	wg.Wait()
+}
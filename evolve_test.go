@@ -0,0 +1,37 @@
+package neat
+
+import "testing"
+
+// TestEvaluateMembersParallelMatchesSerial exercises the worker-pool path
+// evaluateMembers takes when workers > 1 (as OptimizeParallel uses), where
+// each worker evaluates its own clone of a shared Brain concurrently. Run
+// with `go test -race` to confirm those clones never race on Node/nodeState.
+// Results should be identical to the serial path regardless: ReasonAbout is
+// a pure function of the Brain and inputs, so concurrency must not perturb
+// it.
+func TestEvaluateMembersParallelMatchesSerial(t *testing.T) {
+	genes, _ := StartingGenome(3, 2)
+	brain := BuildBrain(genes)
+
+	eval := func(b Brain) float64 {
+		o, _ := b.ReasonAbout([]float64{1, 0, 1}, 3)
+		return o[0]
+	}
+
+	const n = 20
+	serial := make([]Brain, n)
+	parallel := make([]Brain, n)
+	for i := 0; i < n; i++ {
+		serial[i] = brain
+		parallel[i] = brain
+	}
+
+	evaluateMembers(serial, eval, 1)
+	evaluateMembers(parallel, eval, 8)
+
+	for i := 0; i < n; i++ {
+		if serial[i].fitness != parallel[i].fitness {
+			t.Fatalf("member %d: serial fitness %v != parallel fitness %v", i, serial[i].fitness, parallel[i].fitness)
+		}
+	}
+}
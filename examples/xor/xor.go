@@ -23,11 +23,17 @@ func main() {
 	b := p.Champion
 	cases := xorCases()
 	for _, c := range cases {
-		o, _ := b.ReasonAbout(c.x)
+		o, _ := b.ReasonAbout(c.x, activationSteps)
 		fmt.Println(c, o)
 	}
 }
 
+// activationSteps bounds how many activation passes ReasonAbout relaxes a
+// brain over. XOR brains may grow recurrent connections through mutation, so
+// this allows a few passes for feedback to settle rather than assuming a
+// single feed-forward pass.
+const activationSteps = 5
+
 type evalCase struct {
 	x []float64
 	e float64
@@ -47,7 +53,7 @@ func xorEval(b neat.Brain) float64 {
 	var err float64
 
 	for _, c := range cases {
-		o, _ := b.ReasonAbout(c.x)
+		o, _ := b.ReasonAbout(c.x, activationSteps)
 		err += math.Abs(c.e - o[0])
 	}
 	return math.Pow(4-err, 2)
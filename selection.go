@@ -0,0 +1,169 @@
+package neat
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// BehaviorDescriptor summarizes what a Brain actually does, as a point in
+// behavior space, so that novelty search and MAP-Elites can select for
+// behavioral difference or coverage instead of (or alongside) raw fitness.
+type BehaviorDescriptor func(Brain) []float64
+
+func behaviorDistance(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// noveltyScore is a Brain's average distance to its k nearest neighbors,
+// measured across both the current population's behaviors and an archive of
+// past novel individuals, per Lehman & Stanley's novelty search.
+func noveltyScore(behavior []float64, population, archive [][]float64, k int) float64 {
+	distances := make([]float64, 0, len(population)+len(archive))
+	for _, other := range population {
+		distances = append(distances, behaviorDistance(behavior, other))
+	}
+	for _, other := range archive {
+		distances = append(distances, behaviorDistance(behavior, other))
+	}
+	sort.Float64s(distances)
+
+	if k > len(distances) {
+		k = len(distances)
+	}
+	if k == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, d := range distances[:k] {
+		sum += d
+	}
+	return sum / float64(k)
+}
+
+// OptimizeNovelty drives evolution with novelty search instead of raw
+// fitness: each generation a Brain's behavior (via bd) is scored by its
+// average distance to its k nearest neighbors across the rest of the
+// population and an archive of past novel individuals, and that novelty
+// score is used in place of fitness for sharing and selection. Individuals
+// whose novelty exceeds archiveThreshold are added to the archive, so the
+// search keeps pressure toward behaviors unlike anything seen before instead
+// of collapsing onto a single deceptive fitness peak. Because the archive is
+// mutated while scoring, novelty is always evaluated serially.
+func (p *Population) OptimizeNovelty(bd BehaviorDescriptor, numGenerations, k int, archiveThreshold float64) {
+	archive := [][]float64{}
+
+	noveltyEval := func(b Brain) float64 {
+		behavior := bd(b)
+
+		population := make([][]float64, 0, p.size())
+		skippedSelf := false
+		for _, s := range p.species {
+			for _, m := range s.members {
+				if !skippedSelf && m.Genes == b.Genes {
+					skippedSelf = true
+					continue
+				}
+				population = append(population, bd(m))
+			}
+		}
+
+		score := noveltyScore(behavior, population, archive, k)
+		if score > archiveThreshold {
+			archive = append(archive, behavior)
+		}
+		return score
+	}
+
+	p.optimize(noveltyEval, numGenerations, 1)
+}
+
+// CellKey identifies a discretized cell in a MAP-Elites behavior-space grid.
+type CellKey string
+
+// Discretize maps a behavior vector down to the grid cell it falls in, e.g.
+// by bucketing each dimension into fixed-width bins.
+type Discretize func(behavior []float64) CellKey
+
+// MAPElites maintains one elite Brain per behavior-space cell instead of a
+// single best-of population: an offspring only survives if it beats (or
+// fills) the current occupant of the cell its own behavior maps to, which
+// preserves diverse stepping-stone solutions that pure fitness maximization
+// would cull.
+type MAPElites struct {
+	inputs, outputs int
+	nextID          int
+	cells           map[CellKey]Brain
+}
+
+// NewMAPElites creates an empty MAP-Elites archive for brains with the given
+// number of inputs and outputs.
+func NewMAPElites(inputs, outputs int) MAPElites {
+	_, nextID := StartingGenome(inputs, outputs)
+	return MAPElites{inputs, outputs, nextID, map[CellKey]Brain{}}
+}
+
+// Best returns the highest-fitness Brain across all filled cells, and false
+// if the archive is still empty.
+func (m MAPElites) Best() (Brain, bool) {
+	var best Brain
+	found := false
+	for _, b := range m.cells {
+		if !found || b.fitness > best.fitness {
+			best, found = b, true
+		}
+	}
+	return best, found
+}
+
+// Optimize seeds the archive with seedSize random starting brains (if it is
+// still empty), then for numGenerations mutates a random occupant and places
+// the offspring into the cell that disc maps its bd behavior to, keeping
+// whichever of the offspring and the current occupant scores higher under f.
+// It returns an error without running any generations if the archive is
+// still empty after seeding, since there would be no occupant to mutate from.
+func (m *MAPElites) Optimize(f FitnessEval, bd BehaviorDescriptor, disc Discretize, numGenerations, seedSize int) error {
+	if len(m.cells) == 0 {
+		for i := 0; i < seedSize; i++ {
+			genes, nextID := StartingGenome(m.inputs, m.outputs)
+			m.nextID = nextID
+			m.place(BuildBrain(genes), f, bd, disc)
+		}
+	}
+	if len(m.cells) == 0 {
+		return fmt.Errorf("neat: MAPElites archive is empty; call Optimize with seedSize >= 1 first")
+	}
+
+	mtg := []Mutation{}
+	for t := 0; t < numGenerations; t++ {
+		parent := DecodeGenome(m.randomOccupant().Genes)
+
+		var offspring Genome
+		offspring, m.nextID, mtg = parent.Mutate(m.nextID, mtg)
+		m.place(BuildBrain(offspring), f, bd, disc)
+	}
+	return nil
+}
+
+func (m *MAPElites) place(b Brain, f FitnessEval, bd BehaviorDescriptor, disc Discretize) {
+	b.fitness = f(b)
+	cell := disc(bd(b))
+	if current, ok := m.cells[cell]; !ok || b.fitness > current.fitness {
+		m.cells[cell] = b
+	}
+}
+
+func (m MAPElites) randomOccupant() Brain {
+	keys := make([]CellKey, 0, len(m.cells))
+	for k := range m.cells {
+		keys = append(keys, k)
+	}
+	return m.cells[keys[rand.Intn(len(keys))]]
+}
@@ -0,0 +1,25 @@
+package neat
+
+import "testing"
+
+// TestMAPElitesOptimizeEmptyArchiveReturnsError guards the fix in
+// commit f524943: Optimize(f, bd, disc, n, 0) on a fresh MAPElites used to
+// leave the archive empty and then panic inside randomOccupant
+// (rand.Intn(0)). It should return an error instead.
+func TestMAPElitesOptimizeEmptyArchiveReturnsError(t *testing.T) {
+	m := NewMAPElites(3, 1)
+	bd := func(b Brain) []float64 { return []float64{0} }
+	disc := func(behavior []float64) CellKey { return CellKey("only-cell") }
+	f := func(b Brain) float64 { return 0 }
+
+	if err := m.Optimize(f, bd, disc, 5, 0); err == nil {
+		t.Fatal("Optimize with seedSize 0 on an empty archive: got nil error, want a non-nil error")
+	}
+
+	if err := m.Optimize(f, bd, disc, 5, 1); err != nil {
+		t.Fatalf("Optimize with seedSize 1: unexpected error: %v", err)
+	}
+	if _, found := m.Best(); !found {
+		t.Fatal("Optimize with seedSize 1 should have left at least one occupant in the archive")
+	}
+}
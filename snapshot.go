@@ -0,0 +1,342 @@
+package neat
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Snapshots use a small tagged binary format rather than the ';'-delimited
+// string encoding Gene payloads use internally: every scalar field is written
+// at a fixed width (floats as raw IEEE 754 bits, not the hex text the
+// in-memory codec uses) and lists are length-prefixed, so a round trip through Save/LoadPopulation can't lose
+// precision and doesn't require re-parsing delimiter-separated text.
+
+const snapshotMagic uint32 = 0x4e454154 // "NEAT"
+const snapshotVersion uint8 = 1
+
+const (
+	geneTagNode uint8 = iota + 1
+	geneTagConnection
+	geneTagModule
+)
+
+func writeGenomeBinary(w io.Writer, G Genome) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(G))); err != nil {
+		return err
+	}
+	for _, g := range G {
+		var err error
+		switch {
+		case strings.HasPrefix(g.payload, "n"):
+			err = writeNodeGeneBinary(w, decodeNodeGene(g.payload))
+		case strings.HasPrefix(g.payload, "c"):
+			err = writeConnectionGeneBinary(w, decodeConnectionGene(g.payload))
+		case strings.HasPrefix(g.payload, "m"):
+			err = writeModuleGeneBinary(w, decodeModuleGene(g.payload))
+		default:
+			err = fmt.Errorf("neat: unknown gene signature: %s", g.payload)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readGenomeBinary(r io.Reader) (Genome, error) {
+	var numGenes uint32
+	if err := binary.Read(r, binary.BigEndian, &numGenes); err != nil {
+		return nil, err
+	}
+
+	G := make([]Gene, numGenes)
+	for i := range G {
+		var tag uint8
+		if err := binary.Read(r, binary.BigEndian, &tag); err != nil {
+			return nil, err
+		}
+
+		var payload string
+		var mutationID int
+		var err error
+		switch tag {
+		case geneTagNode:
+			var n nodeGene
+			n, err = readNodeGeneBinary(r)
+			mutationID, payload = n.mutationID, n.encode()
+		case geneTagConnection:
+			var c connectionGene
+			c, err = readConnectionGeneBinary(r)
+			mutationID, payload = c.mutationID, c.encode()
+		case geneTagModule:
+			var m moduleGene
+			m, err = readModuleGeneBinary(r)
+			mutationID, payload = m.mutationID, m.encode()
+		default:
+			err = fmt.Errorf("neat: unknown gene tag: %d", tag)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		G[i] = Gene{mutationID, payload}
+	}
+
+	return Genome(G), nil
+}
+
+func writeNodeGeneBinary(w io.Writer, n nodeGene) error {
+	fields := []interface{}{
+		geneTagNode,
+		int64(n.mutationID),
+		int32(n.kind),
+		int32(n.activation),
+		int64(n.moduleID),
+	}
+	return writeFields(w, fields)
+}
+
+func readNodeGeneBinary(r io.Reader) (nodeGene, error) {
+	var mutationID, moduleID int64
+	var kind, activation int32
+	if err := readFields(r, &mutationID, &kind, &activation, &moduleID); err != nil {
+		return nodeGene{}, err
+	}
+	return nodeGene{int(mutationID), NodeKind(kind), ActivationKind(activation), int(moduleID)}, nil
+}
+
+func writeConnectionGeneBinary(w io.Writer, c connectionGene) error {
+	var enabledBit uint8
+	if c.enabled {
+		enabledBit = 1
+	}
+	fields := []interface{}{
+		geneTagConnection,
+		int64(c.mutationID),
+		int64(c.from),
+		int64(c.to),
+		c.weight,
+		enabledBit,
+	}
+	return writeFields(w, fields)
+}
+
+func readConnectionGeneBinary(r io.Reader) (connectionGene, error) {
+	var mutationID, from, to int64
+	var weight float64
+	var enabledBit uint8
+	if err := readFields(r, &mutationID, &from, &to, &weight, &enabledBit); err != nil {
+		return connectionGene{}, err
+	}
+	return connectionGene{int(mutationID), int(from), int(to), weight, enabledBit == 1}, nil
+}
+
+func writeModuleGeneBinary(w io.Writer, m moduleGene) error {
+	if err := writeFields(w, []interface{}{geneTagModule, int64(m.mutationID)}); err != nil {
+		return err
+	}
+	if err := writeIntSlice(w, m.inputs); err != nil {
+		return err
+	}
+	return writeIntSlice(w, m.outputs)
+}
+
+func readModuleGeneBinary(r io.Reader) (moduleGene, error) {
+	var mutationID int64
+	if err := readFields(r, &mutationID); err != nil {
+		return moduleGene{}, err
+	}
+	inputs, err := readIntSlice(r)
+	if err != nil {
+		return moduleGene{}, err
+	}
+	outputs, err := readIntSlice(r)
+	if err != nil {
+		return moduleGene{}, err
+	}
+	return moduleGene{int(mutationID), inputs, outputs}, nil
+}
+
+func writeIntSlice(w io.Writer, xs []int) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(xs))); err != nil {
+		return err
+	}
+	for _, x := range xs {
+		if err := binary.Write(w, binary.BigEndian, int64(x)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readIntSlice(r io.Reader) ([]int, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	xs := make([]int, n)
+	for i := range xs {
+		var x int64
+		if err := binary.Read(r, binary.BigEndian, &x); err != nil {
+			return nil, err
+		}
+		xs[i] = int(x)
+	}
+	return xs, nil
+}
+
+func writeFields(w io.Writer, fields []interface{}) error {
+	for _, f := range fields {
+		if err := binary.Write(w, binary.BigEndian, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readFields(r io.Reader, fields ...interface{}) error {
+	for _, f := range fields {
+		if err := binary.Read(r, binary.BigEndian, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save writes a versioned binary snapshot of the Population to w: every
+// species' members, their fitness and champion, and the nextID innovation
+// counter, so a long-running Optimize can be stopped and later resumed with
+// LoadPopulation and continue assigning innovation numbers without colliding
+// with ones already in use.
+//
+// A species' champion is stored as the index of the member it points to; a
+// champion that is not one of the species' current members is not saved and
+// loads as nil. LoadPopulation recomputes the Population's Champion as the
+// fittest of the restored species champions.
+//
+// Two things a resumed run does NOT get back from a snapshot: the per-
+// generation Mutation dedup history (mtg) that optimize builds fresh for
+// each species every generation is ephemeral by design, scoped to that one
+// breeding pass, and isn't part of Population's state to begin with; and the
+// global math/rand source's state, which Go's rand package doesn't expose
+// for serialization. A resumed run reseeds implicitly (whatever the process
+// does with rand.Seed on startup) rather than replaying the exact same
+// mutation/crossover draws it would have made had it kept running.
+func (p *Population) Save(w io.Writer) error {
+	if err := binary.Write(w, binary.BigEndian, snapshotMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, snapshotVersion); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, int64(p.nextID)); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(p.species))); err != nil {
+		return err
+	}
+
+	for _, s := range p.species {
+		championIdx := int32(-1)
+		for i := range s.members {
+			if s.champion == &s.members[i] {
+				championIdx = int32(i)
+				break
+			}
+		}
+		if err := binary.Write(w, binary.BigEndian, championIdx); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, int64(s.timeWithoutImprovement)); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, uint32(len(s.members))); err != nil {
+			return err
+		}
+		for _, b := range s.members {
+			if err := binary.Write(w, binary.BigEndian, b.fitness); err != nil {
+				return err
+			}
+			if err := writeGenomeBinary(w, DecodeGenome(b.Genes)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadPopulation reconstructs a Population from a snapshot written by
+// Population.Save. See Save's doc comment for what a round trip does and
+// doesn't preserve.
+func LoadPopulation(r io.Reader) (Population, error) {
+	var magic uint32
+	if err := binary.Read(r, binary.BigEndian, &magic); err != nil {
+		return Population{}, err
+	}
+	if magic != snapshotMagic {
+		return Population{}, fmt.Errorf("neat: not a population snapshot")
+	}
+
+	var version uint8
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return Population{}, err
+	}
+	if version != snapshotVersion {
+		return Population{}, fmt.Errorf("neat: unsupported snapshot version %d", version)
+	}
+
+	var nextID int64
+	if err := binary.Read(r, binary.BigEndian, &nextID); err != nil {
+		return Population{}, err
+	}
+
+	var numSpecies uint32
+	if err := binary.Read(r, binary.BigEndian, &numSpecies); err != nil {
+		return Population{}, err
+	}
+
+	species := make([]Species, numSpecies)
+	var champion *Brain
+	for i := range species {
+		var championIdx int32
+		if err := binary.Read(r, binary.BigEndian, &championIdx); err != nil {
+			return Population{}, err
+		}
+		var timeWithoutImprovement int64
+		if err := binary.Read(r, binary.BigEndian, &timeWithoutImprovement); err != nil {
+			return Population{}, err
+		}
+		var numMembers uint32
+		if err := binary.Read(r, binary.BigEndian, &numMembers); err != nil {
+			return Population{}, err
+		}
+
+		members := make([]Brain, numMembers)
+		for j := range members {
+			var fitness float64
+			if err := binary.Read(r, binary.BigEndian, &fitness); err != nil {
+				return Population{}, err
+			}
+			genome, err := readGenomeBinary(r)
+			if err != nil {
+				return Population{}, err
+			}
+			b := BuildBrain(genome)
+			b.fitness = fitness
+			members[j] = b
+		}
+
+		species[i] = Species{members, nil, 0, int(timeWithoutImprovement)}
+		if championIdx >= 0 && int(championIdx) < len(members) {
+			species[i].champion = &species[i].members[championIdx]
+			if champion == nil || species[i].champion.fitness > champion.fitness {
+				champion = species[i].champion
+			}
+		}
+	}
+
+	return Population{species, champion, int(nextID)}, nil
+}
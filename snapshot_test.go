@@ -0,0 +1,62 @@
+package neat
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestPopulationSnapshotRoundTrip checks that saving and loading a Population
+// preserves the innovation counter, species structure, and each member's
+// genome and fitness exactly, per Save/LoadPopulation's doc comments.
+func TestPopulationSnapshotRoundTrip(t *testing.T) {
+	p := NewPopulation(3, 2, 6)
+	p.updateFitnesses(func(b Brain) float64 {
+		o, _ := b.ReasonAbout([]float64{1, 0, 1}, 1)
+		return o[0]
+	}, 1)
+
+	var buf bytes.Buffer
+	if err := p.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadPopulation(&buf)
+	if err != nil {
+		t.Fatalf("LoadPopulation: %v", err)
+	}
+
+	if loaded.nextID != p.nextID {
+		t.Errorf("nextID: got %d, want %d", loaded.nextID, p.nextID)
+	}
+	if len(loaded.species) != len(p.species) {
+		t.Fatalf("species count: got %d, want %d", len(loaded.species), len(p.species))
+	}
+
+	for i, s := range p.species {
+		ls := loaded.species[i]
+		if len(ls.members) != len(s.members) {
+			t.Fatalf("species %d member count: got %d, want %d", i, len(ls.members), len(s.members))
+		}
+		for j, b := range s.members {
+			lb := ls.members[j]
+			if lb.Genes != b.Genes {
+				t.Errorf("species %d member %d: genes did not round-trip\n got:  %s\n want: %s", i, j, lb.Genes, b.Genes)
+			}
+			if lb.fitness != b.fitness {
+				t.Errorf("species %d member %d: fitness got %v, want %v", i, j, lb.fitness, b.fitness)
+			}
+		}
+		if (ls.champion == nil) != (s.champion == nil) {
+			t.Errorf("species %d: champion presence mismatch", i)
+		} else if s.champion != nil && ls.champion.Genes != s.champion.Genes {
+			t.Errorf("species %d: champion genes did not round-trip", i)
+		}
+	}
+
+	if (loaded.Champion == nil) != (p.Champion == nil) {
+		t.Fatalf("population champion presence mismatch")
+	}
+	if p.Champion != nil && loaded.Champion.Genes != p.Champion.Genes {
+		t.Errorf("population champion genes did not round-trip")
+	}
+}